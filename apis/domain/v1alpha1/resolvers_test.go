@@ -0,0 +1,111 @@
+package v1alpha1
+
+import (
+	"context"
+	"testing"
+
+	xpv1 "github.com/crossplane/crossplane-runtime/apis/common/v1"
+	"github.com/crossplane/crossplane-runtime/pkg/meta"
+	"github.com/crossplane/crossplane-runtime/pkg/test"
+	"github.com/google/go-cmp/cmp"
+	kerrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	volumev1alpha1 "github.com/nourspeed/provider-libvirt/apis/volume/v1alpha1"
+)
+
+func TestResolveDiskVolumeID(t *testing.T) {
+	ptr := func(s string) *string { return &s }
+	domain := func(volumeID *string) *Domain {
+		d := &Domain{}
+		d.Spec.ForProvider.Disk = []DiskParameters{{
+			VolumeID:    volumeID,
+			VolumeIDRef: &xpv1.Reference{Name: "centos7"},
+		}}
+		return d
+	}
+	getVolume := func(externalName string) client.Client {
+		return &test.MockClient{
+			MockGet: test.NewMockGetFn(nil, func(obj client.Object) error {
+				if _, ok := obj.(*volumev1alpha1.Volume); !ok {
+					t.Errorf("Get(...): want *Volume, got %T", obj)
+				}
+				if externalName != "" {
+					meta.SetExternalName(obj, externalName)
+				}
+				return nil
+			}),
+		}
+	}
+	notFound := kerrors.NewNotFound(schema.GroupResource{Group: "volume.nourspeed.io", Resource: "volumes"}, "centos7")
+
+	type want struct {
+		volumeID *string
+		err      string
+		notFound bool
+	}
+	cases := map[string]struct {
+		reason string
+		mg     *Domain
+		client client.Client
+		want   want
+	}{
+		"Unresolved": {
+			reason: "A Volume without an external name must not resolve to an empty volumeId.",
+			mg:     domain(nil),
+			client: getVolume(""),
+			want: want{
+				err: "mg.Spec.ForProvider.Disk[i3].VolumeID: referenced field was empty (referenced resource may not yet be ready)",
+			},
+		},
+		"Resolved": {
+			reason: "The referenced Volume's key (its external name) must be copied into volumeId.",
+			mg:     domain(nil),
+			client: getVolume("/var/lib/libvirt/images/centos7.qcow2"),
+			want: want{
+				volumeID: ptr("/var/lib/libvirt/images/centos7.qcow2"),
+			},
+		},
+		"TargetDeleted": {
+			reason: "A missing Volume must surface as NotFound.",
+			mg:     domain(nil),
+			client: &test.MockClient{MockGet: test.NewMockGetFn(notFound)},
+			want: want{
+				notFound: true,
+			},
+		},
+		"TargetDeletedAfterResolution": {
+			reason: "A volumeId that was already resolved is kept when the Volume is later deleted.",
+			mg:     domain(ptr("/var/lib/libvirt/images/centos7.qcow2")),
+			client: &test.MockClient{MockGet: test.NewMockGetFn(notFound)},
+			want: want{
+				volumeID: ptr("/var/lib/libvirt/images/centos7.qcow2"),
+			},
+		},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			err := tc.mg.ResolveReferences(context.Background(), tc.client)
+			switch {
+			case tc.want.notFound:
+				if !kerrors.IsNotFound(err) {
+					t.Errorf("\n%s\nResolveReferences(...): want NotFound error, got %v", tc.reason, err)
+				}
+			case tc.want.err != "":
+				if err == nil || err.Error() != tc.want.err {
+					t.Errorf("\n%s\nResolveReferences(...): want error %q, got %v", tc.reason, tc.want.err, err)
+				}
+			case err != nil:
+				t.Errorf("\n%s\nResolveReferences(...): unexpected error: %v", tc.reason, err)
+			}
+			if err != nil {
+				return
+			}
+			if diff := cmp.Diff(tc.want.volumeID, tc.mg.Spec.ForProvider.Disk[0].VolumeID); diff != "" {
+				t.Errorf("\n%s\nResolveReferences(...): -want volumeId, +got volumeId:\n%s", tc.reason, diff)
+			}
+		})
+	}
+}
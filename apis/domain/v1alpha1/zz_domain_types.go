@@ -103,8 +103,6 @@ type DiskInitParameters struct {
 
 	URL *string `json:"url,omitempty" tf:"url,omitempty"`
 
-	VolumeID *string `json:"volumeId,omitempty" tf:"volume_id,omitempty"`
-
 	Wwn *string `json:"wwn,omitempty" tf:"wwn,omitempty"`
 }
 
@@ -136,9 +134,18 @@ type DiskParameters struct {
 	// +kubebuilder:validation:Optional
 	URL *string `json:"url,omitempty" tf:"url,omitempty"`
 
+	// +crossplane:generate:reference:type=github.com/nourspeed/provider-libvirt/apis/volume/v1alpha1.Volume
 	// +kubebuilder:validation:Optional
 	VolumeID *string `json:"volumeId,omitempty" tf:"volume_id,omitempty"`
 
+	// Reference to a Volume in volume to populate volumeId.
+	// +kubebuilder:validation:Optional
+	VolumeIDRef *v1.Reference `json:"volumeIdRef,omitempty" tf:"-"`
+
+	// Selector for a Volume in volume to populate volumeId.
+	// +kubebuilder:validation:Optional
+	VolumeIDSelector *v1.Selector `json:"volumeIdSelector,omitempty" tf:"-"`
+
 	// +kubebuilder:validation:Optional
 	Wwn *string `json:"wwn,omitempty" tf:"wwn,omitempty"`
 }
@@ -309,11 +309,6 @@ func (in *DiskInitParameters) DeepCopyInto(out *DiskInitParameters) {
 		*out = new(string)
 		**out = **in
 	}
-	if in.VolumeID != nil {
-		in, out := &in.VolumeID, &out.VolumeID
-		*out = new(string)
-		**out = **in
-	}
 	if in.Wwn != nil {
 		in, out := &in.Wwn, &out.Wwn
 		*out = new(string)
@@ -404,6 +399,16 @@ func (in *DiskParameters) DeepCopyInto(out *DiskParameters) {
 		*out = new(string)
 		**out = **in
 	}
+	if in.VolumeIDRef != nil {
+		in, out := &in.VolumeIDRef, &out.VolumeIDRef
+		*out = new(v1.Reference)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.VolumeIDSelector != nil {
+		in, out := &in.VolumeIDSelector, &out.VolumeIDSelector
+		*out = new(v1.Selector)
+		(*in).DeepCopyInto(*out)
+	}
 	if in.Wwn != nil {
 		in, out := &in.Wwn, &out.Wwn
 		*out = new(string)
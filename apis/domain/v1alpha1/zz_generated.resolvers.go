@@ -9,6 +9,7 @@ import (
 	"context"
 	reference "github.com/crossplane/crossplane-runtime/pkg/reference"
 	v1alpha1 "github.com/nourspeed/provider-libvirt/apis/cloudinit/v1alpha1"
+	v1alpha11 "github.com/nourspeed/provider-libvirt/apis/volume/v1alpha1"
 	errors "github.com/pkg/errors"
 	client "sigs.k8s.io/controller-runtime/pkg/client"
 )
@@ -36,5 +37,24 @@ func (mg *Domain) ResolveReferences(ctx context.Context, c client.Reader) error
 	mg.Spec.ForProvider.Cloudinit = reference.ToPtrValue(rsp.ResolvedValue)
 	mg.Spec.ForProvider.CloudinitRef = rsp.ResolvedReference
 
+	for i3 := 0; i3 < len(mg.Spec.ForProvider.Disk); i3++ {
+		rsp, err = r.Resolve(ctx, reference.ResolutionRequest{
+			CurrentValue: reference.FromPtrValue(mg.Spec.ForProvider.Disk[i3].VolumeID),
+			Extract:      reference.ExternalName(),
+			Reference:    mg.Spec.ForProvider.Disk[i3].VolumeIDRef,
+			Selector:     mg.Spec.ForProvider.Disk[i3].VolumeIDSelector,
+			To: reference.To{
+				List:    &v1alpha11.VolumeList{},
+				Managed: &v1alpha11.Volume{},
+			},
+		})
+		if err != nil {
+			return errors.Wrap(err, "mg.Spec.ForProvider.Disk[i3].VolumeID")
+		}
+		mg.Spec.ForProvider.Disk[i3].VolumeID = reference.ToPtrValue(rsp.ResolvedValue)
+		mg.Spec.ForProvider.Disk[i3].VolumeIDRef = rsp.ResolvedReference
+
+	}
+
 	return nil
 }
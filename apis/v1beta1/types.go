@@ -14,6 +14,28 @@ import (
 type ProviderConfigSpec struct {
 	// Credentials required to authenticate to this provider.
 	Credentials ProviderCredentials `json:"credentials"`
+
+	// AllowedPools restricts the libvirt storage pools that Volumes and
+	// cloud-init Disks using this ProviderConfig may be created in. Entries
+	// may use shell-style wildcards such as "tenant-*". A resource that does
+	// not set a pool is checked as the "default" pool. When set, Pools cannot
+	// be created through this ProviderConfig. Pools are matched by name only,
+	// so create them through an unrestricted ProviderConfig and do not let two
+	// pools share a path. An empty list allows every pool. Only resources that
+	// do not exist yet are checked.
+	// +optional
+	AllowedPools []string `json:"allowedPools,omitempty"`
+
+	// AllowedNetworks restricts the libvirt networks that Domains using this
+	// ProviderConfig may attach interfaces to. Entries may use shell-style
+	// wildcards. When set, every interface must use networkName; interfaces
+	// using networkId, bridge, macvtap, vepa or passthrough are rejected, and
+	// Networks cannot be created through this ProviderConfig. Networks are
+	// matched by name only, so a network that attaches to a host bridge
+	// grants access to that bridge. An empty list allows every network. Only
+	// resources that do not exist yet are checked.
+	// +optional
+	AllowedNetworks []string `json:"allowedNetworks,omitempty"`
 }
 
 // ProviderCredentials required to authenticate.
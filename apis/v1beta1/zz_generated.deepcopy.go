@@ -75,6 +75,16 @@ func (in *ProviderConfigList) DeepCopyObject() runtime.Object {
 func (in *ProviderConfigSpec) DeepCopyInto(out *ProviderConfigSpec) {
 	*out = *in
 	in.Credentials.DeepCopyInto(&out.Credentials)
+	if in.AllowedPools != nil {
+		in, out := &in.AllowedPools, &out.AllowedPools
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.AllowedNetworks != nil {
+		in, out := &in.AllowedNetworks, &out.AllowedNetworks
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ProviderConfigSpec.
@@ -0,0 +1,132 @@
+package v1alpha1
+
+import (
+	"context"
+	"testing"
+
+	xpv1 "github.com/crossplane/crossplane-runtime/apis/common/v1"
+	"github.com/crossplane/crossplane-runtime/pkg/meta"
+	"github.com/crossplane/crossplane-runtime/pkg/test"
+	"github.com/google/go-cmp/cmp"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	poolv1alpha1 "github.com/nourspeed/provider-libvirt/apis/pool/v1alpha1"
+)
+
+func TestResolvePool(t *testing.T) {
+	ptr := func(s string) *string { return &s }
+	pool := func(forProviderName, initProviderName, observedName string) poolv1alpha1.Pool {
+		p := poolv1alpha1.Pool{ObjectMeta: metav1.ObjectMeta{Name: "tenant-a"}}
+		meta.SetExternalName(&p, "1b7a5e2c-4d3f-4c4e-9a57-0d8c7e1f2a3b")
+		if forProviderName != "" {
+			p.Spec.ForProvider.Name = ptr(forProviderName)
+		}
+		if initProviderName != "" {
+			p.Spec.InitProvider.Name = ptr(initProviderName)
+		}
+		if observedName != "" {
+			p.Status.AtProvider.Name = ptr(observedName)
+		}
+		return p
+	}
+	getPool := func(p poolv1alpha1.Pool) client.Client {
+		return &test.MockClient{
+			MockGet: test.NewMockGetFn(nil, func(obj client.Object) error {
+				got, ok := obj.(*poolv1alpha1.Pool)
+				if !ok {
+					t.Fatalf("Get(...): want *Pool, got %T", obj)
+				}
+				p.DeepCopyInto(got)
+				return nil
+			}),
+		}
+	}
+	byRef := func() *Volume {
+		v := &Volume{}
+		v.Spec.ForProvider.PoolRef = &xpv1.Reference{Name: "tenant-a"}
+		return v
+	}
+
+	type want struct {
+		pool    *string
+		poolRef *xpv1.Reference
+		err     string
+	}
+	cases := map[string]struct {
+		reason string
+		mg     *Volume
+		client client.Client
+		want   want
+	}{
+		"PoolNotYetObserved": {
+			reason: "A Pool that does not exist on the host yet must not resolve, so the Volume waits for it.",
+			mg:     byRef(),
+			client: getPool(pool("tenant-a", "", "")),
+			want: want{
+				err: "mg.Spec.ForProvider.Pool: referenced field was empty (referenced resource may not yet be ready)",
+			},
+		},
+		"ResolvesToLibvirtNameNotExternalName": {
+			reason: "The pool's libvirt name must be used, not its UUID external name.",
+			mg:     byRef(),
+			client: getPool(pool("tenant-a", "", "tenant-a")),
+			want: want{
+				pool:    ptr("tenant-a"),
+				poolRef: &xpv1.Reference{Name: "tenant-a"},
+			},
+		},
+		"NameOnlyInInitProvider": {
+			reason: "A Pool whose name is only set under spec.initProvider must resolve once observed.",
+			mg:     byRef(),
+			client: getPool(pool("", "tenant-a", "tenant-a")),
+			want: want{
+				pool:    ptr("tenant-a"),
+				poolRef: &xpv1.Reference{Name: "tenant-a"},
+			},
+		},
+		"SelectorMatch": {
+			reason: "A selector must resolve to the matching Pool's libvirt name and record the reference.",
+			mg: func() *Volume {
+				v := &Volume{}
+				v.Spec.ForProvider.PoolSelector = &xpv1.Selector{MatchLabels: map[string]string{"tier": "ssd"}}
+				return v
+			}(),
+			client: &test.MockClient{
+				MockList: test.NewMockListFn(nil, func(obj client.ObjectList) error {
+					l, ok := obj.(*poolv1alpha1.PoolList)
+					if !ok {
+						t.Fatalf("List(...): want *PoolList, got %T", obj)
+					}
+					l.Items = []poolv1alpha1.Pool{pool("tenant-a", "", "tenant-a")}
+					return nil
+				}),
+			},
+			want: want{
+				pool:    ptr("tenant-a"),
+				poolRef: &xpv1.Reference{Name: "tenant-a"},
+			},
+		},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			err := tc.mg.ResolveReferences(context.Background(), tc.client)
+			if tc.want.err != "" {
+				if err == nil || err.Error() != tc.want.err {
+					t.Errorf("\n%s\nResolveReferences(...): want error %q, got %v", tc.reason, tc.want.err, err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("\n%s\nResolveReferences(...): unexpected error: %v", tc.reason, err)
+			}
+			if diff := cmp.Diff(tc.want.pool, tc.mg.Spec.ForProvider.Pool); diff != "" {
+				t.Errorf("\n%s\nResolveReferences(...): -want pool, +got pool:\n%s", tc.reason, diff)
+			}
+			if diff := cmp.Diff(tc.want.poolRef, tc.mg.Spec.ForProvider.PoolRef); diff != "" {
+				t.Errorf("\n%s\nResolveReferences(...): -want poolRef, +got poolRef:\n%s", tc.reason, diff)
+			}
+		})
+	}
+}
@@ -8,6 +8,7 @@ package v1alpha1
 import (
 	"context"
 	reference "github.com/crossplane/crossplane-runtime/pkg/reference"
+	resource "github.com/crossplane/upjet/pkg/resource"
 	v1alpha1 "github.com/nourspeed/provider-libvirt/apis/pool/v1alpha1"
 	errors "github.com/pkg/errors"
 	client "sigs.k8s.io/controller-runtime/pkg/client"
@@ -22,7 +23,7 @@ func (mg *Volume) ResolveReferences(ctx context.Context, c client.Reader) error
 
 	rsp, err = r.Resolve(ctx, reference.ResolutionRequest{
 		CurrentValue: reference.FromPtrValue(mg.Spec.ForProvider.Pool),
-		Extract:      reference.ExternalName(),
+		Extract:      resource.ExtractParamPath("name", true),
 		Reference:    mg.Spec.ForProvider.PoolRef,
 		Selector:     mg.Spec.ForProvider.PoolSelector,
 		To: reference.To{
@@ -1,6 +1,11 @@
 package domain
 
-import "github.com/crossplane/upjet/pkg/config"
+import (
+	"fmt"
+	"net"
+
+	"github.com/crossplane/upjet/pkg/config"
+)
 
 // Configure configures individual resources by adding custom ResourceConfigurators.
 func Configure(p *config.Provider) {
@@ -12,5 +17,47 @@ func Configure(p *config.Provider) {
 		r.References["cloudinit"] = config.Reference{
 			Type: "github.com/nourspeed/provider-libvirt/apis/cloudinit/v1alpha1.Disk",
 		}
+		r.References["disk.volume_id"] = config.Reference{
+			Type: "github.com/nourspeed/provider-libvirt/apis/volume/v1alpha1.Volume",
+		}
+
+		r.Sensitive.AdditionalConnectionDetailsFn = connectionDetails
 	})
 }
+
+// connectionDetails publishes the MAC and the first IPv4 and IPv6 address of
+// every network interface, keyed by the interface index. Addresses are only
+// known once the domain is running and Terraform has seen a lease for it,
+// e.g. with wait_for_lease or the QEMU guest agent.
+func connectionDetails(attr map[string]any) (map[string][]byte, error) {
+	conn := map[string][]byte{}
+	nis, _ := attr["network_interface"].([]any)
+	for i, v := range nis {
+		ni, ok := v.(map[string]any)
+		if !ok {
+			continue
+		}
+		if mac, ok := ni["mac"].(string); ok && mac != "" {
+			conn[fmt.Sprintf("mac_%d", i)] = []byte(mac)
+		}
+		addrs, _ := ni["addresses"].([]any)
+		for _, a := range addrs {
+			s, ok := a.(string)
+			if !ok {
+				continue
+			}
+			ip := net.ParseIP(s)
+			if ip == nil {
+				continue
+			}
+			key := fmt.Sprintf("ipv6_address_%d", i)
+			if ip.To4() != nil {
+				key = fmt.Sprintf("ipv4_address_%d", i)
+			}
+			if _, ok := conn[key]; !ok {
+				conn[key] = []byte(s)
+			}
+		}
+	}
+	return conn, nil
+}
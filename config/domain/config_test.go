@@ -0,0 +1,74 @@
+package domain
+
+import (
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestConnectionDetails(t *testing.T) {
+	cases := map[string]struct {
+		attr map[string]any
+		want map[string][]byte
+	}{
+		"NotRunning": {
+			attr: map[string]any{
+				"name": "vm",
+			},
+			want: map[string][]byte{},
+		},
+		"NoLeaseYet": {
+			attr: map[string]any{
+				"network_interface": []any{
+					map[string]any{"mac": "52:54:00:aa:bb:cc", "addresses": []any{}},
+				},
+			},
+			want: map[string][]byte{
+				"mac_0": []byte("52:54:00:aa:bb:cc"),
+			},
+		},
+		"MultipleInterfacesAndFamilies": {
+			attr: map[string]any{
+				"network_interface": []any{
+					map[string]any{
+						"mac":       "52:54:00:aa:bb:cc",
+						"addresses": []any{"192.168.122.10", "192.168.122.11", "fe80::5054:ff:feaa:bbcc"},
+					},
+					map[string]any{
+						"mac":       "52:54:00:dd:ee:ff",
+						"addresses": []any{"2001:db8::10"},
+					},
+				},
+			},
+			want: map[string][]byte{
+				"mac_0":          []byte("52:54:00:aa:bb:cc"),
+				"ipv4_address_0": []byte("192.168.122.10"),
+				"ipv6_address_0": []byte("fe80::5054:ff:feaa:bbcc"),
+				"mac_1":          []byte("52:54:00:dd:ee:ff"),
+				"ipv6_address_1": []byte("2001:db8::10"),
+			},
+		},
+		"InvalidAddressIgnored": {
+			attr: map[string]any{
+				"network_interface": []any{
+					map[string]any{"addresses": []any{"not-an-ip", "10.0.0.5"}},
+				},
+			},
+			want: map[string][]byte{
+				"ipv4_address_0": []byte("10.0.0.5"),
+			},
+		},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			got, err := connectionDetails(tc.attr)
+			if err != nil {
+				t.Fatalf("connectionDetails(...): unexpected error: %v", err)
+			}
+			if diff := cmp.Diff(tc.want, got); diff != "" {
+				t.Errorf("connectionDetails(...): -want, +got:\n%s", diff)
+			}
+		})
+	}
+}
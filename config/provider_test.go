@@ -0,0 +1,100 @@
+package config
+
+import (
+	"testing"
+	"time"
+
+	ujconfig "github.com/crossplane/upjet/pkg/config"
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestGetProvider(t *testing.T) {
+	type want struct {
+		shortGroup string
+		kind       string
+		references ujconfig.References
+	}
+
+	cases := map[string]want{
+		"libvirt_pool": {
+			shortGroup: "pool",
+			kind:       "Pool",
+		},
+		"libvirt_volume": {
+			shortGroup: "volume",
+			kind:       "Volume",
+			references: ujconfig.References{
+				"pool": {
+					Type:      "github.com/nourspeed/provider-libvirt/apis/pool/v1alpha1.Pool",
+					Extractor: `github.com/crossplane/upjet/pkg/resource.ExtractParamPath("name",true)`,
+				},
+			},
+		},
+		"libvirt_cloudinit_disk": {
+			shortGroup: "cloudinit",
+			kind:       "Disk",
+		},
+		"libvirt_domain": {
+			shortGroup: "domain",
+			kind:       "Domain",
+			references: ujconfig.References{
+				"cloudinit": {
+					Type: "github.com/nourspeed/provider-libvirt/apis/cloudinit/v1alpha1.Disk",
+				},
+				"disk.volume_id": {
+					Type: "github.com/nourspeed/provider-libvirt/apis/volume/v1alpha1.Volume",
+				},
+			},
+		},
+		"libvirt_network": {
+			shortGroup: "network",
+			kind:       "Network",
+		},
+	}
+
+	pc := GetProvider()
+	if diff := cmp.Diff(len(cases), len(pc.Resources)); diff != "" {
+		t.Errorf("GetProvider(): -want resource count, +got resource count:\n%s", diff)
+	}
+
+	for name, w := range cases {
+		t.Run(name, func(t *testing.T) {
+			r, ok := pc.Resources[name]
+			if !ok {
+				t.Fatalf("GetProvider(): resource %q is not configured", name)
+			}
+			if diff := cmp.Diff(w.shortGroup, r.ShortGroup); diff != "" {
+				t.Errorf("ShortGroup: -want, +got:\n%s", diff)
+			}
+			if diff := cmp.Diff(w.kind, r.Kind); diff != "" {
+				t.Errorf("Kind: -want, +got:\n%s", diff)
+			}
+			if r.ExternalName.GetIDFn == nil {
+				t.Errorf("ExternalName: resource %q has no external name configuration", name)
+			}
+			if w.references == nil {
+				w.references = ujconfig.References{}
+			}
+			if diff := cmp.Diff(w.references, r.References); diff != "" {
+				t.Errorf("References: -want, +got:\n%s", diff)
+			}
+		})
+	}
+}
+
+func TestConfigureOperationTimeouts(t *testing.T) {
+	pc := GetProvider()
+	ConfigureOperationTimeouts(pc, OperationTimeouts{DomainCreate: 10 * time.Minute})
+
+	if diff := cmp.Diff(10*time.Minute, pc.Resources["libvirt_domain"].OperationTimeouts.Create); diff != "" {
+		t.Errorf("libvirt_domain create timeout: -want, +got:\n%s", diff)
+	}
+	for name, r := range pc.Resources {
+		if name == "libvirt_domain" {
+			continue
+		}
+		if r.OperationTimeouts.Create != 0 {
+			t.Errorf("%s: create timeout set on a resource without a timeouts block", name)
+		}
+	}
+}
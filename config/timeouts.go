@@ -0,0 +1,29 @@
+/*
+Copyright 2022 Upbound Inc.
+*/
+
+package config
+
+import (
+	"time"
+
+	ujconfig "github.com/crossplane/upjet/pkg/config"
+)
+
+// OperationTimeouts holds the operation timeouts that can be tuned at runtime.
+// A zero value leaves the Terraform provider's own default in place.
+type OperationTimeouts struct {
+	// DomainCreate bounds a libvirt_domain create, which includes waiting
+	// for DHCP leases when wait_for_lease is set on an interface.
+	DomainCreate time.Duration
+}
+
+// ConfigureOperationTimeouts applies the supplied timeouts to the resources
+// of the given provider. Only resources whose Terraform schema has a timeouts
+// block can be configured; libvirt_domain is currently the only one and it
+// only supports a create timeout.
+func ConfigureOperationTimeouts(pc *ujconfig.Provider, t OperationTimeouts) {
+	if r, ok := pc.Resources["libvirt_domain"]; ok {
+		r.OperationTimeouts.Create = t.DomainCreate
+	}
+}
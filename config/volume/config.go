@@ -9,8 +9,13 @@ func Configure(p *config.Provider) {
         // this resource, which would be "libvirt"
         r.ShortGroup = "volume"
 
+        // libvirt_volume takes the pool name, while the external name of a
+        // Pool is its libvirt UUID, so resolve to status.atProvider.name.
+        // That is only set once the pool exists on the host, so Volumes wait
+        // for their Pool, and it also covers a name set in initProvider.
         r.References["pool"] = config.Reference{
-            Type: "github.com/nourspeed/provider-libvirt/apis/pool/v1alpha1.Pool",
+            Type:      "github.com/nourspeed/provider-libvirt/apis/pool/v1alpha1.Pool",
+            Extractor: `github.com/crossplane/upjet/pkg/resource.ExtractParamPath("name",true)`,
         }
     })
 }
\ No newline at end of file
@@ -0,0 +1,191 @@
+/*
+Copyright 2022 Upbound Inc.
+*/
+
+package clients
+
+import (
+	"path"
+	"strings"
+
+	"github.com/crossplane/crossplane-runtime/pkg/meta"
+	"github.com/crossplane/crossplane-runtime/pkg/resource"
+	ujresource "github.com/crossplane/upjet/pkg/resource"
+	"github.com/pkg/errors"
+
+	cloudinitv1alpha1 "github.com/nourspeed/provider-libvirt/apis/cloudinit/v1alpha1"
+	domainv1alpha1 "github.com/nourspeed/provider-libvirt/apis/domain/v1alpha1"
+	networkv1alpha1 "github.com/nourspeed/provider-libvirt/apis/network/v1alpha1"
+	poolv1alpha1 "github.com/nourspeed/provider-libvirt/apis/pool/v1alpha1"
+	"github.com/nourspeed/provider-libvirt/apis/v1beta1"
+	volumev1alpha1 "github.com/nourspeed/provider-libvirt/apis/volume/v1alpha1"
+)
+
+const (
+	// defaultPool is the pool the Terraform provider uses when a volume or
+	// cloud-init disk does not set one.
+	defaultPool = "default"
+
+	errPoolNotAllowed      = "pool %q is not allowed by ProviderConfig %q, allowed pools: %s"
+	errNetworkNotAllowed   = "network %q is not allowed by ProviderConfig %q, allowed networks: %s"
+	errNetworkNameRequired = "network interface %d does not set networkName, which ProviderConfig %q requires because it restricts networks"
+	errNetworkFieldDenied  = "network interface %d sets %s, which ProviderConfig %q does not allow because it restricts networks to networkName"
+	errCheckAllowlist      = "cannot check allowlist of ProviderConfig %q"
+	errPoolCreateDenied    = "ProviderConfig %q restricts pools, so it cannot be used to create Pools"
+	errNetworkCreateDenied = "ProviderConfig %q restricts networks, so it cannot be used to create Networks"
+)
+
+// checkAllowed returns an error if the supplied managed resource refers to a
+// pool or network that the ProviderConfig does not allow. Pools and Networks
+// cannot be created through a ProviderConfig that restricts them, because a
+// new pool or network with an allowed name could alias the storage path or
+// bridge of one that is not allowed. The check only guards creation:
+// resources that already exist or are being deleted are not checked, so
+// narrowing an allowlist never blocks their deletion.
+func checkAllowed(mg resource.Managed, pc *v1beta1.ProviderConfig) error {
+	if meta.WasDeleted(mg) || observed(mg) {
+		return nil
+	}
+	switch r := mg.(type) {
+	case *poolv1alpha1.Pool:
+		if len(pc.Spec.AllowedPools) > 0 {
+			return errors.Errorf(errPoolCreateDenied, pc.GetName())
+		}
+	case *networkv1alpha1.Network:
+		if len(pc.Spec.AllowedNetworks) > 0 {
+			return errors.Errorf(errNetworkCreateDenied, pc.GetName())
+		}
+	case *volumev1alpha1.Volume:
+		return checkPools(pc, r.Spec.ForProvider.Pool)
+	case *cloudinitv1alpha1.Disk:
+		return checkPools(pc, r.Spec.ForProvider.Pool, r.Spec.InitProvider.Pool)
+	case *domainv1alpha1.Domain:
+		return checkNetworkInterfaces(pc, r.Spec.ForProvider.NetworkInterface, r.Spec.InitProvider.NetworkInterface)
+	}
+	return nil
+}
+
+// observed reports whether the controller has recorded a Terraform ID in
+// status.atProvider, which only happens once the resource exists. Unlike the
+// external name annotation, users cannot set it on a new resource.
+func observed(mg resource.Managed) bool {
+	o, ok := mg.(ujresource.Observable)
+	if !ok {
+		return false
+	}
+	obs, err := o.GetObservation()
+	if err != nil {
+		return false
+	}
+	id, _ := obs["id"].(string)
+	return id != ""
+}
+
+// checkPools checks every pool that is set. If none is set the volume ends up
+// in the default pool, so that is checked instead.
+func checkPools(pc *v1beta1.ProviderConfig, pools ...*string) error {
+	if len(pc.Spec.AllowedPools) == 0 {
+		return nil
+	}
+	names := make([]string, 0, len(pools))
+	for _, p := range pools {
+		if p != nil {
+			names = append(names, *p)
+		}
+	}
+	if len(names) == 0 {
+		names = append(names, defaultPool)
+	}
+	for _, n := range names {
+		ok, err := allowed(pc.Spec.AllowedPools, n)
+		if err != nil {
+			return errors.Wrapf(err, errCheckAllowlist, pc.GetName())
+		}
+		if !ok {
+			return errors.Errorf(errPoolNotAllowed, n, pc.GetName(), strings.Join(pc.Spec.AllowedPools, ", "))
+		}
+	}
+	return nil
+}
+
+// checkNetworkInterfaces requires every interface to select its network by an
+// allowed networkName. The spec.forProvider and spec.initProvider entries at
+// the same index describe the same interface, so they are checked together.
+func checkNetworkInterfaces(pc *v1beta1.ProviderConfig, fp []domainv1alpha1.NetworkInterfaceParameters, ip []domainv1alpha1.NetworkInterfaceInitParameters) error {
+	if len(pc.Spec.AllowedNetworks) == 0 {
+		return nil
+	}
+	n := len(fp)
+	if len(ip) > n {
+		n = len(ip)
+	}
+	for i := 0; i < n; i++ {
+		var names []*string
+		if i < len(fp) {
+			if f := otherNetworkField(fp[i].NetworkID, fp[i].Bridge, fp[i].Macvtap, fp[i].Vepa, fp[i].Passthrough); f != "" {
+				return errors.Errorf(errNetworkFieldDenied, i, f, pc.GetName())
+			}
+			names = append(names, fp[i].NetworkName)
+		}
+		if i < len(ip) {
+			if f := otherNetworkField(ip[i].NetworkID, ip[i].Bridge, ip[i].Macvtap, ip[i].Vepa, ip[i].Passthrough); f != "" {
+				return errors.Errorf(errNetworkFieldDenied, i, f, pc.GetName())
+			}
+			names = append(names, ip[i].NetworkName)
+		}
+		checked := false
+		for _, name := range names {
+			if name == nil {
+				continue
+			}
+			checked = true
+			ok, err := allowed(pc.Spec.AllowedNetworks, *name)
+			if err != nil {
+				return errors.Wrapf(err, errCheckAllowlist, pc.GetName())
+			}
+			if !ok {
+				return errors.Errorf(errNetworkNotAllowed, *name, pc.GetName(), strings.Join(pc.Spec.AllowedNetworks, ", "))
+			}
+		}
+		if !checked {
+			return errors.Errorf(errNetworkNameRequired, i, pc.GetName())
+		}
+	}
+	return nil
+}
+
+// otherNetworkField returns the name of the first set field that attaches an
+// interface to something other than a named libvirt network.
+func otherNetworkField(networkID, bridge, macvtap, vepa, passthrough *string) string {
+	switch {
+	case networkID != nil:
+		return "networkId"
+	case bridge != nil:
+		return "bridge"
+	case macvtap != nil:
+		return "macvtap"
+	case vepa != nil:
+		return "vepa"
+	case passthrough != nil:
+		return "passthrough"
+	}
+	return ""
+}
+
+// allowed reports whether name matches one of the supplied patterns. An empty
+// pattern list allows every name. Every pattern is validated, so a malformed
+// entry is reported even if another entry matches.
+func allowed(patterns []string, name string) (bool, error) {
+	if len(patterns) == 0 {
+		return true, nil
+	}
+	matched := false
+	for _, p := range patterns {
+		ok, err := path.Match(p, name)
+		if err != nil {
+			return false, errors.Wrapf(err, "invalid pattern %q", p)
+		}
+		matched = matched || ok
+	}
+	return matched, nil
+}
@@ -0,0 +1,247 @@
+package clients
+
+import (
+	"path"
+	"testing"
+	"time"
+
+	"github.com/crossplane/crossplane-runtime/pkg/meta"
+	"github.com/crossplane/crossplane-runtime/pkg/resource"
+	"github.com/crossplane/crossplane-runtime/pkg/test"
+	"github.com/google/go-cmp/cmp"
+	"github.com/pkg/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	cloudinitv1alpha1 "github.com/nourspeed/provider-libvirt/apis/cloudinit/v1alpha1"
+	domainv1alpha1 "github.com/nourspeed/provider-libvirt/apis/domain/v1alpha1"
+	networkv1alpha1 "github.com/nourspeed/provider-libvirt/apis/network/v1alpha1"
+	poolv1alpha1 "github.com/nourspeed/provider-libvirt/apis/pool/v1alpha1"
+	"github.com/nourspeed/provider-libvirt/apis/v1beta1"
+	volumev1alpha1 "github.com/nourspeed/provider-libvirt/apis/volume/v1alpha1"
+)
+
+func TestCheckAllowed(t *testing.T) {
+	ptr := func(s string) *string { return &s }
+	pc := func(pools, networks []string) *v1beta1.ProviderConfig {
+		return &v1beta1.ProviderConfig{
+			ObjectMeta: metav1.ObjectMeta{Name: "test-config"},
+			Spec: v1beta1.ProviderConfigSpec{
+				AllowedPools:    pools,
+				AllowedNetworks: networks,
+			},
+		}
+	}
+	volume := func(pool string) *volumev1alpha1.Volume {
+		v := &volumev1alpha1.Volume{}
+		v.Spec.ForProvider.Pool = ptr(pool)
+		return v
+	}
+	disk := func(pool string) *cloudinitv1alpha1.Disk {
+		d := &cloudinitv1alpha1.Disk{}
+		d.Spec.ForProvider.Pool = ptr(pool)
+		return d
+	}
+	deleted := func(mg resource.Managed) resource.Managed {
+		mg.SetDeletionTimestamp(&metav1.Time{Time: time.Now()})
+		return mg
+	}
+	forged := func(mg resource.Managed) resource.Managed {
+		meta.SetExternalName(mg, "2f1c6c8e-6b1f-4a57-9d55-6f6e8a3c1b2d")
+		return mg
+	}
+	domain := func(networks ...string) *domainv1alpha1.Domain {
+		d := &domainv1alpha1.Domain{}
+		for _, n := range networks {
+			d.Spec.ForProvider.NetworkInterface = append(d.Spec.ForProvider.NetworkInterface, domainv1alpha1.NetworkInterfaceParameters{NetworkName: ptr(n)})
+		}
+		return d
+	}
+
+	cases := map[string]struct {
+		mg   resource.Managed
+		pc   *v1beta1.ProviderConfig
+		want error
+	}{
+		"EmptyAllowsEveryPool": {
+			mg: volume("backup"),
+			pc: pc(nil, nil),
+		},
+		"VolumePoolAllowed": {
+			mg: volume("tenant-a"),
+			pc: pc([]string{"default", "tenant-a"}, nil),
+		},
+		"VolumePoolWildcard": {
+			mg: volume("tenant-b"),
+			pc: pc([]string{"tenant-*"}, nil),
+		},
+		"VolumePoolDenied": {
+			mg:   volume("backup"),
+			pc:   pc([]string{"default", "tenant-*"}, nil),
+			want: errors.Errorf(errPoolNotAllowed, "backup", "test-config", "default, tenant-*"),
+		},
+		"DiskPoolDenied": {
+			mg:   disk("backup"),
+			pc:   pc([]string{"default"}, nil),
+			want: errors.Errorf(errPoolNotAllowed, "backup", "test-config", "default"),
+		},
+		"DomainNetworksAllowed": {
+			mg: domain("default", "tenant-net"),
+			pc: pc(nil, []string{"default", "tenant-*"}),
+		},
+		"DomainNetworkDenied": {
+			mg:   domain("default", "management"),
+			pc:   pc(nil, []string{"default"}),
+			want: errors.Errorf(errNetworkNotAllowed, "management", "test-config", "default"),
+		},
+		"VolumeWithoutPoolUsesDefault": {
+			mg: &volumev1alpha1.Volume{},
+			pc: pc([]string{"default", "tenant-*"}, nil),
+		},
+		"VolumeWithoutPoolDenied": {
+			mg:   &volumev1alpha1.Volume{},
+			pc:   pc([]string{"tenant-*"}, nil),
+			want: errors.Errorf(errPoolNotAllowed, "default", "test-config", "tenant-*"),
+		},
+		"DiskWithoutPoolDenied": {
+			mg:   &cloudinitv1alpha1.Disk{},
+			pc:   pc([]string{"tenant-*"}, nil),
+			want: errors.Errorf(errPoolNotAllowed, "default", "test-config", "tenant-*"),
+		},
+		"DiskInitProviderPoolDenied": {
+			mg: func() resource.Managed {
+				d := &cloudinitv1alpha1.Disk{}
+				d.Spec.InitProvider.Pool = ptr("backup")
+				return d
+			}(),
+			pc:   pc([]string{"tenant-*"}, nil),
+			want: errors.Errorf(errPoolNotAllowed, "backup", "test-config", "tenant-*"),
+		},
+		"DomainWithoutNetworkNameDenied": {
+			mg: func() resource.Managed {
+				d := &domainv1alpha1.Domain{}
+				d.Spec.ForProvider.NetworkInterface = []domainv1alpha1.NetworkInterfaceParameters{{}}
+				return d
+			}(),
+			pc:   pc(nil, []string{"default"}),
+			want: errors.Errorf(errNetworkNameRequired, 0, "test-config"),
+		},
+		"DomainWithoutInterfaces": {
+			mg: &domainv1alpha1.Domain{},
+			pc: pc(nil, []string{"default"}),
+		},
+		"DomainBridgeDenied": {
+			mg: func() resource.Managed {
+				d := domain("default")
+				d.Spec.ForProvider.NetworkInterface = append(d.Spec.ForProvider.NetworkInterface, domainv1alpha1.NetworkInterfaceParameters{Bridge: ptr("br-mgmt")})
+				return d
+			}(),
+			pc:   pc(nil, []string{"*"}),
+			want: errors.Errorf(errNetworkFieldDenied, 1, "bridge", "test-config"),
+		},
+		"DomainNetworkIDDenied": {
+			mg: func() resource.Managed {
+				d := domain("default")
+				d.Spec.ForProvider.NetworkInterface[0].NetworkID = ptr("2f1c6c8e-0000-0000-0000-000000000000")
+				return d
+			}(),
+			pc:   pc(nil, []string{"default"}),
+			want: errors.Errorf(errNetworkFieldDenied, 0, "networkId", "test-config"),
+		},
+		"DomainInitProviderMacvtapDenied": {
+			mg: func() resource.Managed {
+				d := domain("default")
+				d.Spec.InitProvider.NetworkInterface = []domainv1alpha1.NetworkInterfaceInitParameters{{Macvtap: ptr("eth0")}}
+				return d
+			}(),
+			pc:   pc(nil, []string{"default"}),
+			want: errors.Errorf(errNetworkFieldDenied, 0, "macvtap", "test-config"),
+		},
+		"DomainInitProviderNetworkName": {
+			mg: func() resource.Managed {
+				d := &domainv1alpha1.Domain{}
+				d.Spec.InitProvider.NetworkInterface = []domainv1alpha1.NetworkInterfaceInitParameters{{NetworkName: ptr("tenant-net")}}
+				return d
+			}(),
+			pc: pc(nil, []string{"tenant-*"}),
+		},
+		"DomainBridgeAllowedWithoutNetworkAllowlist": {
+			mg: func() resource.Managed {
+				d := &domainv1alpha1.Domain{}
+				d.Spec.ForProvider.NetworkInterface = []domainv1alpha1.NetworkInterfaceParameters{{Bridge: ptr("br0")}}
+				return d
+			}(),
+			pc: pc([]string{"default"}, nil),
+		},
+		"BadPoolPattern": {
+			mg:   volume("tenant-a"),
+			pc:   pc([]string{"tenant-*", "tenant-["}, nil),
+			want: errors.Wrapf(errors.Wrapf(path.ErrBadPattern, "invalid pattern %q", "tenant-["), errCheckAllowlist, "test-config"),
+		},
+		"BadNetworkPattern": {
+			mg:   domain("default"),
+			pc:   pc(nil, []string{"[default"}),
+			want: errors.Wrapf(errors.Wrapf(path.ErrBadPattern, "invalid pattern %q", "[default"), errCheckAllowlist, "test-config"),
+		},
+		"DeletedVolumeNotChecked": {
+			mg: deleted(volume("backup")),
+			pc: pc([]string{"tenant-*"}, nil),
+		},
+		"DeletedDomainNotChecked": {
+			mg: deleted(domain("management")),
+			pc: pc(nil, []string{"default"}),
+		},
+		"ExistingVolumeNotChecked": {
+			mg: func() resource.Managed {
+				v := volume("backup")
+				v.Status.AtProvider.ID = ptr("/var/lib/libvirt/backup/data.qcow2")
+				return v
+			}(),
+			pc: pc([]string{"tenant-*"}, nil),
+		},
+		"ForgedExternalNameVolumeChecked": {
+			mg:   forged(volume("backup")),
+			pc:   pc([]string{"tenant-*"}, nil),
+			want: errors.Errorf(errPoolNotAllowed, "backup", "test-config", "tenant-*"),
+		},
+		"ForgedExternalNameDomainChecked": {
+			mg:   forged(domain("management")),
+			pc:   pc(nil, []string{"default"}),
+			want: errors.Errorf(errNetworkNotAllowed, "management", "test-config", "default"),
+		},
+		"PoolCreateDenied": {
+			mg:   &poolv1alpha1.Pool{},
+			pc:   pc([]string{"tenant-*"}, nil),
+			want: errors.Errorf(errPoolCreateDenied, "test-config"),
+		},
+		"PoolCreateAllowedWithoutPoolAllowlist": {
+			mg: &poolv1alpha1.Pool{},
+			pc: pc(nil, []string{"default"}),
+		},
+		"ExistingPoolNotChecked": {
+			mg: func() resource.Managed {
+				p := &poolv1alpha1.Pool{}
+				p.Status.AtProvider.ID = ptr("1b7a5e2c-0000-0000-0000-000000000000")
+				return p
+			}(),
+			pc: pc([]string{"tenant-*"}, nil),
+		},
+		"NetworkCreateDenied": {
+			mg:   &networkv1alpha1.Network{},
+			pc:   pc(nil, []string{"tenant-*"}),
+			want: errors.Errorf(errNetworkCreateDenied, "test-config"),
+		},
+		"DeletedNetworkNotChecked": {
+			mg: deleted(&networkv1alpha1.Network{}),
+			pc: pc(nil, []string{"tenant-*"}),
+		},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			err := checkAllowed(tc.mg, tc.pc)
+			if diff := cmp.Diff(tc.want, err, test.EquateErrors()); diff != "" {
+				t.Errorf("checkAllowed(...): -want error, +got error:\n%s", diff)
+			}
+		})
+	}
+}
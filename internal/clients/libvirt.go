@@ -5,7 +5,10 @@ Copyright 2021 Upbound Inc.
 package clients
 
 import (
+	"bytes"
 	"context"
+	"crypto/sha256"
+	"encoding/hex"
 	"encoding/json"
 	"io/ioutil"
 	"os"
@@ -26,7 +29,11 @@ const (
 	keyCACert   = "cacert"     // CA certificate content
 	keyPKIPath  = "pkipath"    // Custom PKI directory path
 	keyNoVerify = "no_verify"  // Skip certificate verification
-	
+
+	// pkiBaseDir is where inline CA certificates are written, one
+	// directory per distinct certificate.
+	pkiBaseDir = "/tmp/libvirt-pki"
+
 	// error messages
 	errNoProviderConfig     = "no providerConfigRef provided"
 	errGetProviderConfig    = "cannot get referenced ProviderConfig"
@@ -57,6 +64,10 @@ func TerraformSetupBuilder(version, providerSource, providerVersion string) terr
 			return ps, errors.Wrap(err, errGetProviderConfig)
 		}
 
+		if err := checkAllowed(mg, pc); err != nil {
+			return ps, err
+		}
+
 		t := resource.NewProviderConfigUsageTracker(client, &v1beta1.ProviderConfigUsage{})
 		if err := t.Track(ctx, mg); err != nil {
 			return ps, errors.Wrap(err, errTrackUsage)
@@ -79,7 +90,7 @@ func TerraformSetupBuilder(version, providerSource, providerVersion string) terr
 
 		// Handle custom CA certificate
 		if caCert, ok := creds[keyCACert]; ok && caCert != "" {
-			pkiPath := "/tmp/libvirt-pki"
+			pkiPath := pkiPathFor(caCert)
 			if err := setupCustomCA(pkiPath, caCert); err != nil {
 				return ps, errors.Wrap(err, errSetupCustomCA)
 			}
@@ -103,6 +114,16 @@ func TerraformSetupBuilder(version, providerSource, providerVersion string) terr
 	}
 }
 
+// pkiPathFor returns the PKI directory for the supplied CA certificate. The
+// directory is derived from the certificate content, so ProviderConfigs with
+// different CAs never share a directory and a rotated CA gets a new one while
+// Terraform operations started with the old configuration keep reading the
+// old file.
+func pkiPathFor(caCert string) string {
+	sum := sha256.Sum256([]byte(caCert))
+	return filepath.Join(pkiBaseDir, hex.EncodeToString(sum[:8]))
+}
+
 // setupCustomCA creates a PKI directory with the provided CA certificate
 func setupCustomCA(pkiPath, caCert string) error {
 	// Create PKI directory
@@ -110,9 +131,31 @@ func setupCustomCA(pkiPath, caCert string) error {
 		return errors.Wrap(err, "cannot create PKI directory")
 	}
 
-	// Write CA certificate
+	// Nothing to do if the certificate is already in place, which is the
+	// case for every reconcile but the first.
 	caPath := filepath.Join(pkiPath, "cacert.pem")
-	if err := ioutil.WriteFile(caPath, []byte(caCert), 0644); err != nil {
+	if existing, err := ioutil.ReadFile(caPath); err == nil && bytes.Equal(existing, []byte(caCert)) {
+		return nil
+	}
+
+	// Write CA certificate via a rename so that a concurrent reader never
+	// sees a partially written file.
+	tmp, err := ioutil.TempFile(pkiPath, "cacert-*.pem")
+	if err != nil {
+		return errors.Wrap(err, "cannot write CA certificate")
+	}
+	defer os.Remove(tmp.Name()) //nolint:errcheck // Only fails if the rename below succeeded.
+	if _, err := tmp.WriteString(caCert); err != nil {
+		tmp.Close() //nolint:errcheck // We're already returning an error.
+		return errors.Wrap(err, "cannot write CA certificate")
+	}
+	if err := tmp.Close(); err != nil {
+		return errors.Wrap(err, "cannot write CA certificate")
+	}
+	if err := os.Chmod(tmp.Name(), 0644); err != nil {
+		return errors.Wrap(err, "cannot write CA certificate")
+	}
+	if err := os.Rename(tmp.Name(), caPath); err != nil {
 		return errors.Wrap(err, "cannot write CA certificate")
 	}
 
@@ -11,12 +11,15 @@ import (
 	"github.com/crossplane/crossplane-runtime/pkg/resource"
 	"github.com/crossplane/crossplane-runtime/pkg/test"
 	"github.com/google/go-cmp/cmp"
-	"github.com/pkg/errors"
+	corev1 "k8s.io/api/core/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
 	"sigs.k8s.io/controller-runtime/pkg/client"
 
 	xpv1 "github.com/crossplane/crossplane-runtime/apis/common/v1"
+	"github.com/crossplane/upjet/pkg/terraform"
+
 	"github.com/nourspeed/provider-libvirt/apis/v1beta1"
 )
 
@@ -28,7 +31,7 @@ func TestTerraformSetupBuilder_CustomCA(t *testing.T) {
 	}
 	type want struct {
 		err    error
-		config map[string]any
+		config terraform.ProviderConfiguration
 	}
 
 	testCA := "-----BEGIN CERTIFICATE-----\nMIIC...TEST CA CERTIFICATE...==\n-----END CERTIFICATE-----"
@@ -65,7 +68,7 @@ func TestTerraformSetupBuilder_CustomCA(t *testing.T) {
 				},
 			},
 			want: want{
-				config: map[string]any{
+				config: terraform.ProviderConfiguration{
 					"uri": "qemu+tls://test.example.com/system",
 				},
 			},
@@ -96,9 +99,9 @@ func TestTerraformSetupBuilder_CustomCA(t *testing.T) {
 				},
 			},
 			want: want{
-				config: map[string]any{
+				config: terraform.ProviderConfiguration{
 					"uri":     "qemu+tls://test.example.com/system",
-					"pkipath": "/tmp/libvirt-pki",
+					"pkipath": pkiPathFor(testCA),
 				},
 			},
 		},
@@ -128,7 +131,7 @@ func TestTerraformSetupBuilder_CustomCA(t *testing.T) {
 				},
 			},
 			want: want{
-				config: map[string]any{
+				config: terraform.ProviderConfiguration{
 					"uri":       "qemu+tls://test.example.com/system",
 					"no_verify": "true",
 				},
@@ -160,8 +163,8 @@ func TestTerraformSetupBuilder_CustomCA(t *testing.T) {
 			credJSON, _ := json.Marshal(credData)
 
 			// Create mock client
-			client := test.NewMockClient()
-			client.MockGet = test.NewMockGetFn(nil, func(obj client.Object) error {
+			kube := test.NewMockClient()
+			kube.MockGet = test.NewMockGetFn(nil, func(obj client.Object) error {
 				switch o := obj.(type) {
 				case *v1beta1.ProviderConfig:
 					*o = *tc.pc
@@ -177,7 +180,7 @@ func TestTerraformSetupBuilder_CustomCA(t *testing.T) {
 
 			// Create setup function and execute
 			setupFn := TerraformSetupBuilder(tc.args.version, tc.args.providerSource, tc.args.providerVersion)
-			got, err := setupFn(context.Background(), client, tc.mg)
+			got, err := setupFn(context.Background(), kube, tc.mg)
 
 			// Verify results
 			if diff := cmp.Diff(tc.want.err, err, test.EquateErrors()); diff != "" {
@@ -195,7 +198,7 @@ func TestTerraformSetupBuilder_CustomCA(t *testing.T) {
 
 			// For CustomCA test, verify the CA file was created
 			if name == "CustomCA" {
-				pkiPath := "/tmp/libvirt-pki"
+				pkiPath := pkiPathFor(testCA)
 				caPath := filepath.Join(pkiPath, "cacert.pem")
 				if _, err := os.Stat(caPath); os.IsNotExist(err) {
 					t.Errorf("CA certificate file was not created at %s", caPath)
@@ -244,6 +247,25 @@ func TestSetupCustomCA(t *testing.T) {
 		t.Errorf("CA file content = %q, want %q", string(content), testCA)
 	}
 
+	// Setting up the same certificate again must leave the file in place.
+	before, err := os.Stat(caPath)
+	if err != nil {
+		t.Errorf("Cannot stat CA file: %v", err)
+		return
+	}
+	if err := setupCustomCA(tmpDir, testCA); err != nil {
+		t.Errorf("setupCustomCA() second call error = %v", err)
+		return
+	}
+	after, err := os.Stat(caPath)
+	if err != nil {
+		t.Errorf("Cannot stat CA file: %v", err)
+		return
+	}
+	if !os.SameFile(before, after) {
+		t.Errorf("setupCustomCA() rewrote an unchanged CA certificate")
+	}
+
 	// Verify file permissions
 	info, err := os.Stat(caPath)
 	if err != nil {
@@ -257,8 +279,24 @@ func TestSetupCustomCA(t *testing.T) {
 	}
 }
 
+func TestPKIPathFor(t *testing.T) {
+	caA := "-----BEGIN CERTIFICATE-----\nCA A\n-----END CERTIFICATE-----"
+	caB := "-----BEGIN CERTIFICATE-----\nCA B\n-----END CERTIFICATE-----"
+
+	if pkiPathFor(caA) != pkiPathFor(caA) {
+		t.Errorf("pkiPathFor() is not stable for the same certificate")
+	}
+	if pkiPathFor(caA) == pkiPathFor(caB) {
+		t.Errorf("pkiPathFor() = %q for two different certificates", pkiPathFor(caA))
+	}
+	if filepath.Dir(pkiPathFor(caA)) != pkiBaseDir {
+		t.Errorf("pkiPathFor() = %q, want a directory under %q", pkiPathFor(caA), pkiBaseDir)
+	}
+}
+
 // Mock implementation for testing
 type mockManaged struct {
+	metav1.ObjectMeta
 	ref *xpv1.Reference
 }
 
@@ -272,10 +310,10 @@ func (m *mockManaged) GetProviderReference() *xpv1.Reference { return nil }
 func (m *mockManaged) SetProviderReference(r *xpv1.Reference) {}
 func (m *mockManaged) GetPublishConnectionDetailsTo() *xpv1.PublishConnectionDetailsTo { return nil }
 func (m *mockManaged) SetPublishConnectionDetailsTo(p *xpv1.PublishConnectionDetailsTo) {}
-func (m *mockManaged) GetWriteConnectionSecretsToReference() *xpv1.SecretReference { return nil }
-func (m *mockManaged) SetWriteConnectionSecretsToReference(r *xpv1.SecretReference) {}
+func (m *mockManaged) GetWriteConnectionSecretToReference() *xpv1.SecretReference { return nil }
+func (m *mockManaged) SetWriteConnectionSecretToReference(r *xpv1.SecretReference) {}
 func (m *mockManaged) GetCondition(ct xpv1.ConditionType) xpv1.Condition { return xpv1.Condition{} }
 func (m *mockManaged) SetConditions(c ...xpv1.Condition) {}
-func (m *mockManaged) GetObjectKind() runtime.ObjectKind { return nil }
+func (m *mockManaged) GetObjectKind() schema.ObjectKind { return schema.EmptyObjectKind }
 func (m *mockManaged) DeepCopyObject() runtime.Object { return m }
 func (m *mockManaged) GetObjectMeta() metav1.Object { return &metav1.ObjectMeta{} }
\ No newline at end of file